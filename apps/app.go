@@ -0,0 +1,332 @@
+// Package apps provides the data model for Marathon applications and
+// tasks, along with the logic used to translate them into Consul service
+// registrations.
+package apps
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AppId is the Marathon application identifier, e.g. "/group/subgroup/name".
+type AppId string
+
+func (id AppId) String() string {
+	return string(id)
+}
+
+// TaskId is the Marathon task identifier.
+type TaskId string
+
+func (id TaskId) String() string {
+	return string(id)
+}
+
+// App represents a Marathon application as returned by /v2/apps.
+type App struct {
+	ID              AppId             `json:"id"`
+	Labels          map[string]string `json:"labels"`
+	HealthChecks    []HealthCheck     `json:"healthChecks"`
+	ReadinessChecks []ReadinessCheck  `json:"readinessChecks"`
+	PortDefinitions []PortDefinition  `json:"portDefinitions"`
+	Tasks           []Task            `json:"tasks"`
+}
+
+// HealthCheck mirrors a single entry of Marathon's "healthChecks" array.
+type HealthCheck struct {
+	Path                   string `json:"path"`
+	PortIndex              int    `json:"portIndex"`
+	Protocol               string `json:"protocol"`
+	GracePeriodSeconds     int    `json:"gracePeriodSeconds"`
+	IntervalSeconds        int    `json:"intervalSeconds"`
+	TimeoutSeconds         int    `json:"timeoutSeconds"`
+	MaxConsecutiveFailures int    `json:"maxConsecutiveFailures"`
+}
+
+// ReadinessCheck mirrors a single entry of Marathon's "readinessChecks"
+// array. Unlike health checks, readiness checks only run while a
+// deployment is in progress, gating traffic on new instances until they
+// report ready.
+type ReadinessCheck struct {
+	Name                    string `json:"name"`
+	Protocol                string `json:"protocol"`
+	Path                    string `json:"path"`
+	PortName                string `json:"portName"`
+	IntervalSeconds         int    `json:"intervalSeconds"`
+	TimeoutSeconds          int    `json:"timeoutSeconds"`
+	HTTPStatusCodesForReady []int  `json:"httpStatusCodesForReady"`
+	PreserveLastResponse    bool   `json:"preserveLastResponse"`
+}
+
+// PortDefinition mirrors a single entry of Marathon's "portDefinitions"
+// array, used to carry per-port labels such as a custom Consul service
+// name or tags.
+type PortDefinition struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// Task represents a Marathon task, either embedded in an App or returned
+// by /v2/apps/{appId}/tasks.
+type Task struct {
+	ID                    TaskId                 `json:"id"`
+	AppID                 AppId                  `json:"appId"`
+	Host                  string                 `json:"host"`
+	Ports                 []int                  `json:"ports"`
+	HealthCheckResults    []HealthCheckResult    `json:"healthCheckResults"`
+	ReadinessCheckResults []ReadinessCheckResult `json:"readinessCheckResults"`
+}
+
+// HealthCheckResult mirrors a single entry of a task's
+// "healthCheckResults" array.
+type HealthCheckResult struct {
+	Alive bool `json:"alive"`
+}
+
+// ReadinessCheckResult mirrors a single entry of a task's
+// "readinessCheckResults" array.
+type ReadinessCheckResult struct {
+	Name   string `json:"name"`
+	TaskID string `json:"taskId"`
+	Ready  bool   `json:"ready"`
+}
+
+// IsReady reports whether task may be registered in Consul with respect
+// to app's readiness checks. Apps without readiness checks are always
+// ready. For an app with readiness checks, a task reporting results is
+// only ready once every reported check has ready:true, which keeps it out
+// of rotation until its checks pass instead of registering it the moment
+// it has ports assigned. Marathon clears readinessCheckResults once the
+// deployment finishes, so a task reporting no results is ready; this
+// includes a task that has not reported any results yet during a
+// deployment.
+func (t *Task) IsReady(app *App) bool {
+	if len(app.ReadinessChecks) == 0 {
+		return true
+	}
+	for _, result := range t.ReadinessCheckResults {
+		if !result.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// IsConsulApp returns true if the app carries a "consul" label, meaning
+// it should be registered in Consul.
+func (app *App) IsConsulApp() bool {
+	_, ok := app.Labels["consul"]
+	return ok
+}
+
+// RegistrationIntent describes a single Consul service registration
+// derived from a Marathon task. HealthCheck is nil when the app defines
+// no HealthCheck targeting this intent's port.
+type RegistrationIntent struct {
+	Name        string
+	Port        int
+	Tags        []string
+	HealthCheck *HealthCheck
+}
+
+// RegistrationIntent builds the single Consul service registration for
+// task, for apps that expose only one Consul service per task. It picks
+// the first port unless a PortDefinition carries a "consul" label, in
+// which case that port's index is used instead. The service name
+// defaults to the app's Marathon id, overridden by the selected
+// PortDefinition's (or, failing that, the app's) "consul" label when it
+// holds a custom name rather than the bare "true" flag. Apps that need
+// one Consul service per port should use RegistrationIntents instead.
+//
+// RegistrationIntents falls back to this method, rather than the other
+// way around, because its single-service semantics predate the
+// multi-service convention and are kept bug-for-bug as they were: a labelled
+// port's tags here still follow the baseline-protected value=="tag"
+// idiom (see tags()/labelTags()), not the "<name>-tag" convention
+// portTags() uses for genuine multi-service intents. Delegating in the
+// other direction would either have to duplicate that legacy tag/name
+// handling inside RegistrationIntents or silently change it for existing
+// single-service callers.
+func (app *App) RegistrationIntent(task *Task, nameSeparator string) RegistrationIntent {
+	portIndex, portLabel, hasPortLabel := app.consulPortDefinition()
+
+	port := task.Ports[0]
+	if hasPortLabel && portIndex < len(task.Ports) {
+		port = task.Ports[portIndex]
+	}
+
+	name := app.defaultName(nameSeparator)
+	if hasPortLabel && portLabel != "true" {
+		if escaped, ok := escapeName(portLabel, nameSeparator); ok {
+			name = escaped
+		}
+	} else if appLabel, ok := app.Labels["consul"]; ok && appLabel != "true" {
+		if escaped, ok := escapeName(appLabel, nameSeparator); ok {
+			name = escaped
+		}
+	}
+
+	return RegistrationIntent{
+		Name:        name,
+		Port:        port,
+		Tags:        app.tags(portIndex, hasPortLabel),
+		HealthCheck: app.healthCheckForPort(portIndex),
+	}
+}
+
+// RegistrationIntents returns one RegistrationIntent per PortDefinition
+// carrying a "consul" label, so a single task can register as several
+// distinct Consul services (e.g. separate "http", "admin" and "metrics"
+// services for the same task). Each intent's name comes from that port's
+// "consul" label when it holds a custom name, falling back to the app
+// name plus a suffix taken from the port's "consul-service-suffix" label
+// (or the port's index if that label is absent too); its tags come from
+// the app's own labels plus that port's "<name>-tag" labels via
+// portTags(). Apps with no labelled PortDefinition fall back to the
+// single intent RegistrationIntent would produce.
+func (app *App) RegistrationIntents(task *Task, nameSeparator string) []RegistrationIntent {
+	var intents []RegistrationIntent
+	for index, portDefinition := range app.PortDefinitions {
+		portLabel, hasPortLabel := portDefinition.Labels["consul"]
+		if !hasPortLabel || index >= len(task.Ports) {
+			continue
+		}
+
+		intents = append(intents, RegistrationIntent{
+			Name:        app.portServiceName(index, portLabel, nameSeparator),
+			Port:        task.Ports[index],
+			Tags:        app.portTags(index),
+			HealthCheck: app.healthCheckForPort(index),
+		})
+	}
+
+	if len(intents) == 0 {
+		return []RegistrationIntent{app.RegistrationIntent(task, nameSeparator)}
+	}
+	return intents
+}
+
+// healthCheckForPort returns the app's HealthCheck targeting portIndex,
+// or nil if it defines none.
+func (app *App) healthCheckForPort(portIndex int) *HealthCheck {
+	for i, healthCheck := range app.HealthChecks {
+		if healthCheck.PortIndex == portIndex {
+			return &app.HealthChecks[i]
+		}
+	}
+	return nil
+}
+
+// portServiceName derives the service name for the PortDefinition at
+// portIndex: portLabel itself when it holds a custom name, otherwise the
+// app name with a separator-joined suffix from that port's
+// "consul-service-suffix" label, falling back to the port's index.
+func (app *App) portServiceName(portIndex int, portLabel, nameSeparator string) string {
+	if portLabel != "true" {
+		if escaped, ok := escapeName(portLabel, nameSeparator); ok {
+			return escaped
+		}
+	}
+
+	suffix := app.PortDefinitions[portIndex].Labels["consul-service-suffix"]
+	if suffix == "" {
+		suffix = strconv.Itoa(portIndex)
+	}
+	return app.defaultName(nameSeparator) + nameSeparator + suffix
+}
+
+// consulPortDefinition returns the index and "consul" label value of the
+// first PortDefinition carrying one, or ok=false if none does.
+func (app *App) consulPortDefinition() (index int, value string, ok bool) {
+	for i, portDefinition := range app.PortDefinitions {
+		if v, present := portDefinition.Labels["consul"]; present {
+			return i, v, true
+		}
+	}
+	return 0, "", false
+}
+
+// defaultName derives a Consul service name from the app's Marathon id,
+// e.g. "/rootGroup/subGroup/name" with separator "." becomes
+// "rootGroup.subGroup.name".
+func (app *App) defaultName(nameSeparator string) string {
+	trimmed := strings.TrimPrefix(string(app.ID), "/")
+	return strings.Join(strings.Split(trimmed, "/"), nameSeparator)
+}
+
+// escapeName sanitizes a user-provided "consul" label value into a valid
+// service name, reporting ok=false if nothing usable is left.
+func escapeName(value, nameSeparator string) (string, bool) {
+	trimmed := strings.Trim(strings.TrimSpace(value), "/")
+	if trimmed == "" {
+		return "", false
+	}
+	return strings.Replace(trimmed, "/", nameSeparator, -1), true
+}
+
+// tags collects the Consul tags for a registration: every app label whose
+// value is "tag" becomes a tag, plus, when portIndex points at a labelled
+// PortDefinition, every one of that port's labels whose value is "tag".
+func (app *App) tags(portIndex int, hasPortLabel bool) []string {
+	tags := labelTags(app.Labels)
+	if hasPortLabel && portIndex < len(app.PortDefinitions) {
+		tags = append(tags, labelTags(app.PortDefinitions[portIndex].Labels)...)
+	}
+	return tags
+}
+
+func labelTags(labels map[string]string) []string {
+	tags := make([]string, 0, len(labels))
+	for key, value := range labels {
+		if value == "tag" {
+			tags = append(tags, key)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// portTags collects the Consul tags for a per-port registration produced
+// by RegistrationIntents: the app's own value=="tag" labels via
+// labelTags(), plus every one of that PortDefinition's labels whose key
+// ends in "-tag", whose value is used as the tag itself (e.g.
+// "metrics-tag": "v1" yields the tag "v1"). This mirrors how other
+// Marathon integrations namespace per-port labels.
+func (app *App) portTags(portIndex int) []string {
+	tags := labelTags(app.Labels)
+	for key, value := range app.PortDefinitions[portIndex].Labels {
+		if strings.HasSuffix(key, "-tag") {
+			tags = append(tags, value)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+type appsResponse struct {
+	Apps []*App `json:"apps"`
+}
+
+type appResponse struct {
+	App *App `json:"app"`
+}
+
+// ParseApps parses the response body of GET /v2/apps into a slice of App.
+func ParseApps(jsonBlob []byte) ([]*App, error) {
+	response := &appsResponse{}
+	if err := json.Unmarshal(jsonBlob, response); err != nil {
+		return nil, fmt.Errorf("could not parse apps: %s", err)
+	}
+	return response.Apps, nil
+}
+
+// ParseApp parses the response body of GET /v2/apps/{appId} into an App.
+func ParseApp(jsonBlob []byte) (*App, error) {
+	response := &appResponse{}
+	if err := json.Unmarshal(jsonBlob, response); err != nil {
+		return &App{}, fmt.Errorf("could not parse app: %s", err)
+	}
+	return response.App, nil
+}
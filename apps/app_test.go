@@ -87,6 +87,74 @@ func TestParseApp(t *testing.T) {
 	assert.Equal(t, expected, app)
 }
 
+func TestParseApp_OnMalformedJsonReturnsNonNilAppWithError(t *testing.T) {
+	t.Parallel()
+
+	app, err := ParseApp([]byte(""))
+	assert.Error(t, err)
+	assert.NotNil(t, app)
+}
+
+func TestParseApp_WithReadinessChecks(t *testing.T) {
+	t.Parallel()
+
+	appBlob, _ := ioutil.ReadFile("app_readiness.json")
+
+	app, err := ParseApp(appBlob)
+	assert.NoError(t, err)
+	assert.Len(t, app.ReadinessChecks, 1)
+	assert.Equal(t, "responseCheck", app.ReadinessChecks[0].Name)
+	assert.Len(t, app.Tasks, 3)
+
+	readyTask := app.Tasks[0]
+	assert.True(t, readyTask.IsReady(app))
+
+	deployingTask := app.Tasks[1]
+	assert.False(t, deployingTask.IsReady(app))
+
+	pendingTask := app.Tasks[2]
+	assert.False(t, pendingTask.IsReady(app))
+}
+
+func TestTaskIsReady_NoReadinessChecksDefined(t *testing.T) {
+	t.Parallel()
+
+	app := &App{}
+	task := &Task{}
+
+	assert.True(t, task.IsReady(app))
+}
+
+func TestTaskIsReady_AllChecksReady(t *testing.T) {
+	t.Parallel()
+
+	app := &App{ReadinessChecks: []ReadinessCheck{{Name: "responseCheck"}}}
+	task := &Task{ReadinessCheckResults: []ReadinessCheckResult{{Name: "responseCheck", Ready: true}}}
+
+	assert.True(t, task.IsReady(app))
+}
+
+func TestTaskIsReady_SomeChecksNotReady(t *testing.T) {
+	t.Parallel()
+
+	app := &App{ReadinessChecks: []ReadinessCheck{{Name: "responseCheck"}, {Name: "warmupCheck"}}}
+	task := &Task{ReadinessCheckResults: []ReadinessCheckResult{
+		{Name: "responseCheck", Ready: true},
+		{Name: "warmupCheck", Ready: false},
+	}}
+
+	assert.False(t, task.IsReady(app))
+}
+
+func TestTaskIsReady_NoResultsOutsideOfADeploymentIsReady(t *testing.T) {
+	t.Parallel()
+
+	app := &App{ReadinessChecks: []ReadinessCheck{{Name: "responseCheck"}}}
+	task := &Task{}
+
+	assert.True(t, task.IsReady(app))
+}
+
 func TestConsulApp(t *testing.T) {
 	t.Parallel()
 
@@ -320,6 +388,77 @@ func TestRegistrationIntent_PickDifferentPortViaPortDefinitions(t *testing.T) {
 	assert.Equal(t, 5678, intent.Port)
 }
 
+func TestRegistrationIntents_OneServicePerLabelledPort(t *testing.T) {
+	t.Parallel()
+
+	// given
+	app := &App{
+		ID:     "app-name",
+		Labels: map[string]string{"private": "tag"},
+		HealthChecks: []HealthCheck{
+			{Path: "/admin/health", PortIndex: 1},
+		},
+		PortDefinitions: []PortDefinition{
+			{Labels: map[string]string{"consul": "http", "public-tag": "public"}},
+			{Labels: map[string]string{"consul": "true", "consul-service-suffix": "admin"}},
+			{},
+		},
+	}
+	task := &Task{
+		Ports: []int{1234, 5678, 9012},
+	}
+
+	// when
+	intents := app.RegistrationIntents(task, "-")
+
+	// then
+	assert.Len(t, intents, 2)
+
+	assert.Equal(t, "http", intents[0].Name)
+	assert.Equal(t, 1234, intents[0].Port)
+	assert.Equal(t, []string{"private", "public"}, intents[0].Tags)
+	assert.Nil(t, intents[0].HealthCheck)
+
+	assert.Equal(t, "app-name-admin", intents[1].Name)
+	assert.Equal(t, 5678, intents[1].Port)
+	assert.Equal(t, []string{"private"}, intents[1].Tags)
+	assert.Equal(t, &app.HealthChecks[0], intents[1].HealthCheck)
+}
+
+func TestRegistrationIntents_PortTagValueIsTakenVerbatimFromTheLabel(t *testing.T) {
+	t.Parallel()
+
+	// given
+	app := &App{
+		ID: "app-name",
+		PortDefinitions: []PortDefinition{
+			{Labels: map[string]string{"consul": "metrics", "metrics-tag": "v1", "region-tag": "us-east"}},
+		},
+	}
+	task := &Task{Ports: []int{9090}}
+
+	// when
+	intents := app.RegistrationIntents(task, "-")
+
+	// then
+	assert.Len(t, intents, 1)
+	assert.Equal(t, []string{"us-east", "v1"}, intents[0].Tags)
+}
+
+func TestRegistrationIntents_FallsBackToSingleIntentWithoutLabelledPorts(t *testing.T) {
+	t.Parallel()
+
+	// given
+	app := &App{ID: "app-name"}
+	task := &Task{Ports: []int{1234, 5678}}
+
+	// when
+	intents := app.RegistrationIntents(task, "-")
+
+	// then
+	assert.Equal(t, []RegistrationIntent{app.RegistrationIntent(task, "-")}, intents)
+}
+
 func TestRegistrationIntent_PickFirstMatchingPortDefinitionIfMultipleContainConsulLabel(t *testing.T) {
 	t.Parallel()
 
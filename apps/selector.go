@@ -0,0 +1,279 @@
+package apps
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Selector decides whether an App matches an operator-defined app
+// selection expression built by ParseSelector.
+type Selector interface {
+	Eval(app *App) bool
+}
+
+// FilterApps returns the subset of allApps matched by selector.
+func FilterApps(allApps []*App, selector Selector) []*App {
+	filtered := make([]*App, 0, len(allApps))
+	for _, app := range allApps {
+		if selector.Eval(app) {
+			filtered = append(filtered, app)
+		}
+	}
+	return filtered
+}
+
+// LabelQueryConstraints returns the "key" and "key==value" label
+// constraints that are safe to push down into Marathon's
+// "label=k==v,k2" query parameter: every constraint ANDed together at
+// the top of selector. It is intentionally conservative - constraints
+// beneath an OR or NOT, label inequalities and app-id matches are left
+// for client-side evaluation - since Marathon's query only needs to
+// narrow the candidate set; the full expression is still evaluated
+// locally via FilterApps.
+func LabelQueryConstraints(selector Selector) []string {
+	switch s := selector.(type) {
+	case *andSelector:
+		return append(LabelQueryConstraints(s.left), LabelQueryConstraints(s.right)...)
+	case *labelEqualsSelector:
+		return []string{s.key + "==" + s.value}
+	case *labelPresenceSelector:
+		return []string{s.key}
+	default:
+		return nil
+	}
+}
+
+type andSelector struct{ left, right Selector }
+
+func (s *andSelector) Eval(app *App) bool { return s.left.Eval(app) && s.right.Eval(app) }
+
+type orSelector struct{ left, right Selector }
+
+func (s *orSelector) Eval(app *App) bool { return s.left.Eval(app) || s.right.Eval(app) }
+
+type notSelector struct{ inner Selector }
+
+func (s *notSelector) Eval(app *App) bool { return !s.inner.Eval(app) }
+
+type labelPresenceSelector struct{ key string }
+
+func (s *labelPresenceSelector) Eval(app *App) bool {
+	_, ok := app.Labels[s.key]
+	return ok
+}
+
+type labelEqualsSelector struct{ key, value string }
+
+func (s *labelEqualsSelector) Eval(app *App) bool {
+	value, ok := app.Labels[s.key]
+	return ok && value == s.value
+}
+
+type labelNotEqualsSelector struct{ key, value string }
+
+func (s *labelNotEqualsSelector) Eval(app *App) bool {
+	value, ok := app.Labels[s.key]
+	return !ok || value != s.value
+}
+
+type idGlobSelector struct{ pattern string }
+
+func (s *idGlobSelector) Eval(app *App) bool {
+	matched, err := path.Match(s.pattern, string(app.ID))
+	return err == nil && matched
+}
+
+type idRegexSelector struct{ re *regexp.Regexp }
+
+func (s *idRegexSelector) Eval(app *App) bool {
+	return s.re.MatchString(string(app.ID))
+}
+
+// ParseSelector parses an app selection expression built from label
+// equality/inequality ("env==production", "consul-sync!=false"), bare
+// label presence ("consul"), app-id glob or regex matches
+// ("id==/prod/*", "id=~^/prod/.*$"), parentheses and the boolean
+// operators AND, OR and NOT, e.g.
+// "id==/prod/* AND env==production AND NOT consul-sync==false".
+func ParseSelector(expr string) (Selector, error) {
+	parser := &selectorParser{tokens: tokenizeSelector(expr)}
+	selector, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos != len(parser.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in selector %q", parser.tokens[parser.pos], expr)
+	}
+	return selector, nil
+}
+
+type selectorParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *selectorParser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *selectorParser) next() (string, bool) {
+	token, ok := p.peek()
+	if ok {
+		p.pos++
+	}
+	return token, ok
+}
+
+func (p *selectorParser) parseOr() (Selector, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token != "OR" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orSelector{left: left, right: right}
+	}
+}
+
+func (p *selectorParser) parseAnd() (Selector, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token, ok := p.peek()
+		if !ok || token != "AND" {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andSelector{left: left, right: right}
+	}
+}
+
+func (p *selectorParser) parseNot() (Selector, error) {
+	if token, ok := p.peek(); ok && token == "NOT" {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notSelector{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *selectorParser) parsePrimary() (Selector, error) {
+	token, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of selector")
+	}
+
+	if token == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.next(); !ok || closing != ")" {
+			return nil, fmt.Errorf("expected closing ')'")
+		}
+		return inner, nil
+	}
+	if token == ")" {
+		return nil, fmt.Errorf("unexpected ')'")
+	}
+
+	key := token
+	op, hasOp := p.peek()
+	if !hasOp || (op != "==" && op != "!=" && op != "=~") {
+		return &labelPresenceSelector{key: key}, nil
+	}
+	p.next()
+
+	value, ok := p.next()
+	if !ok {
+		return nil, fmt.Errorf("expected value after %q %s", key, op)
+	}
+
+	if key == "id" {
+		switch op {
+		case "==":
+			return &idGlobSelector{pattern: value}, nil
+		case "=~":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid id regex %q: %s", value, err)
+			}
+			return &idRegexSelector{re: re}, nil
+		default:
+			return nil, fmt.Errorf("id does not support operator %q", op)
+		}
+	}
+
+	switch op {
+	case "==":
+		return &labelEqualsSelector{key: key, value: value}, nil
+	case "!=":
+		return &labelNotEqualsSelector{key: key, value: value}, nil
+	default:
+		return nil, fmt.Errorf("label %q does not support operator %q", key, op)
+	}
+}
+
+// tokenizeSelector splits expr into identifiers, "(" / ")", and the
+// "==" / "!=" / "=~" operators, which need not be surrounded by
+// whitespace (e.g. "env==production").
+func tokenizeSelector(expr string) []string {
+	var tokens []string
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			tokens = append(tokens, buf.String())
+			buf.Reset()
+		}
+	}
+
+	runes := []rune(expr)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		case c == '=' && i+1 < len(runes) && runes[i+1] == '=':
+			flush()
+			tokens = append(tokens, "==")
+			i++
+		case c == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			flush()
+			tokens = append(tokens, "!=")
+			i++
+		case c == '=' && i+1 < len(runes) && runes[i+1] == '~':
+			flush()
+			tokens = append(tokens, "=~")
+			i++
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	flush()
+	return tokens
+}
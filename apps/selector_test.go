@@ -0,0 +1,121 @@
+package apps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSelector_ParserErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []string{
+		"",
+		"env==",
+		"(env==production",
+		"env==production)",
+		"id=~[",
+	}
+
+	for _, expr := range cases {
+		_, err := ParseSelector(expr)
+		assert.Error(t, err, "expected %q to fail to parse", expr)
+	}
+}
+
+func TestParseSelector_ValidExpressions(t *testing.T) {
+	t.Parallel()
+
+	cases := []string{
+		"consul",
+		"env==production",
+		"consul-sync!=false",
+		"id==/prod/*",
+		"id=~^/prod/.*$",
+		"env==production AND NOT consul-sync==false",
+		"(env==production OR env==staging) AND consul",
+		"NOT NOT consul",
+	}
+
+	for _, expr := range cases {
+		_, err := ParseSelector(expr)
+		assert.NoError(t, err, "expected %q to parse", expr)
+	}
+}
+
+func TestSelector_Eval(t *testing.T) {
+	t.Parallel()
+
+	prodApp := &App{ID: "/prod/webapp", Labels: map[string]string{"env": "production", "consul": "true"}}
+	stagingApp := &App{ID: "/staging/webapp", Labels: map[string]string{"env": "staging"}}
+	noSyncApp := &App{ID: "/prod/legacy", Labels: map[string]string{"env": "production", "consul-sync": "false"}}
+
+	matrix := []struct {
+		expr string
+		app  *App
+		want bool
+	}{
+		{"consul", prodApp, true},
+		{"consul", stagingApp, false},
+		{"env==production", prodApp, true},
+		{"env==production", stagingApp, false},
+		{"env!=production", stagingApp, true},
+		{"env!=production", prodApp, false},
+		{"missing-label!=anything", prodApp, true},
+		{"id==/prod/*", prodApp, true},
+		{"id==/prod/*", stagingApp, false},
+		{"id=~^/prod/.*$", noSyncApp, true},
+		{"id=~^/staging/.*$", noSyncApp, false},
+		{"env==production AND NOT consul-sync==false", prodApp, true},
+		{"env==production AND NOT consul-sync==false", noSyncApp, false},
+		{"env==production OR env==staging", stagingApp, true},
+		{"consul-sync==false OR env==staging", stagingApp, true},
+		{"(env==production OR env==staging) AND consul", prodApp, true},
+		{"(env==production OR env==staging) AND consul", stagingApp, false},
+	}
+
+	for _, testCase := range matrix {
+		selector, err := ParseSelector(testCase.expr)
+		assert.NoError(t, err, testCase.expr)
+		assert.Equal(t, testCase.want, selector.Eval(testCase.app), "expr=%q app=%s", testCase.expr, testCase.app.ID)
+	}
+}
+
+func TestFilterApps(t *testing.T) {
+	t.Parallel()
+
+	// given
+	prodApp := &App{ID: "/prod/webapp", Labels: map[string]string{"consul": "true"}}
+	otherApp := &App{ID: "/other/webapp", Labels: map[string]string{}}
+	selector, err := ParseSelector("consul")
+	assert.NoError(t, err)
+
+	// when
+	filtered := FilterApps([]*App{prodApp, otherApp}, selector)
+
+	// then
+	assert.Equal(t, []*App{prodApp}, filtered)
+}
+
+func TestLabelQueryConstraints(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		expr string
+		want []string
+	}{
+		{"consul", []string{"consul"}},
+		{"env==production", []string{"env==production"}},
+		{"env==production AND consul", []string{"env==production", "consul"}},
+		{"env!=production", nil},
+		{"id==/prod/*", nil},
+		{"env==production OR consul", nil},
+		{"NOT consul", nil},
+	}
+
+	for _, testCase := range cases {
+		selector, err := ParseSelector(testCase.expr)
+		assert.NoError(t, err, testCase.expr)
+		assert.Equal(t, testCase.want, LabelQueryConstraints(selector), testCase.expr)
+	}
+}
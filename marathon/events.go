@@ -0,0 +1,215 @@
+package marathon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EventType identifies the kind of payload carried by an Event.
+type EventType string
+
+const (
+	EventTypeStatusUpdate          EventType = "status_update_event"
+	EventTypeHealthStatusChanged   EventType = "health_status_changed_event"
+	EventTypeDeploymentStepSuccess EventType = "deployment_step_success"
+	EventTypeAPIPost               EventType = "api_post_event"
+	EventTypeLeaderElected         EventType = "leader_elected"
+)
+
+// Event is a single decoded frame from Marathon's /v2/events SSE stream.
+// Exactly one of the typed fields matching Type is populated; unrecognized
+// event types are dropped rather than delivered half-decoded.
+type Event struct {
+	Type EventType
+
+	StatusUpdate          *StatusUpdateEvent
+	HealthStatusChanged   *HealthStatusChangedEvent
+	DeploymentStepSuccess *DeploymentStepSuccessEvent
+	APIPost               *APIPostEvent
+	LeaderElected         *LeaderElectedEvent
+}
+
+// StatusUpdateEvent mirrors Marathon's "status_update_event", sent when a
+// task transitions between states (e.g. TASK_RUNNING, TASK_KILLED).
+type StatusUpdateEvent struct {
+	TaskID     string `json:"taskId"`
+	AppID      string `json:"appId"`
+	TaskStatus string `json:"taskStatus"`
+	Host       string `json:"host"`
+	Ports      []int  `json:"ports"`
+}
+
+// HealthStatusChangedEvent mirrors Marathon's "health_status_changed_event".
+type HealthStatusChangedEvent struct {
+	AppID  string `json:"appId"`
+	TaskID string `json:"taskId"`
+	Alive  bool   `json:"alive"`
+}
+
+// DeploymentStepSuccessEvent mirrors Marathon's "deployment_step_success".
+type DeploymentStepSuccessEvent struct {
+	ID string `json:"id"`
+}
+
+// APIPostEvent mirrors Marathon's "api_post_event", sent whenever an app
+// is created or updated through the REST API.
+type APIPostEvent struct {
+	ClientIP string `json:"clientIp"`
+	URI      string `json:"uri"`
+}
+
+// LeaderElectedEvent mirrors Marathon's "leader_elected" event, sent
+// whenever cluster leadership changes and carrying no payload of its own.
+type LeaderElectedEvent struct {
+}
+
+const (
+	eventsInitialBackoff = 1 * time.Second
+	eventsMaxBackoff     = 30 * time.Second
+	// eventsHealthyConnectionDuration is how long a connection has to stay
+	// up before a subsequent drop resets the backoff back to its initial
+	// value, rather than continuing to grow.
+	eventsHealthyConnectionDuration = eventsMaxBackoff
+)
+
+// Events opens a streaming connection to Marathon's /v2/events endpoint
+// and delivers decoded events on the returned channel until ctx is
+// cancelled, at which point the channel is closed. The connection is
+// reconnected automatically, with capped exponential backoff, whenever it
+// drops or Marathon returns an error; 303 redirects to the current leader
+// are followed transparently by the underlying http.Client.
+func (m *Marathon) Events(ctx context.Context) (<-chan Event, error) {
+	events := make(chan Event)
+	go m.streamEvents(ctx, events)
+	return events, nil
+}
+
+func (m *Marathon) streamEvents(ctx context.Context, events chan<- Event) {
+	defer close(events)
+
+	backoff := eventsInitialBackoff
+	for ctx.Err() == nil {
+		connectedAt := time.Now()
+		m.readEvents(ctx, events)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(connectedAt) >= eventsHealthyConnectionDuration {
+			backoff = eventsInitialBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > eventsMaxBackoff {
+			backoff = eventsMaxBackoff
+		}
+	}
+}
+
+// readEvents opens a single SSE connection and blocks, delivering events
+// until the stream ends or errors.
+func (m *Marathon) readEvents(ctx context.Context, events chan<- Event) error {
+	request, err := http.NewRequest("GET", m.url("/v2/events"), nil)
+	if err != nil {
+		return err
+	}
+	request = request.WithContext(ctx)
+	request.Header.Set("Accept", "text/event-stream")
+	if token := m.currentToken(); token != "" {
+		request.Header.Set("Authorization", "token="+token)
+	}
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET /v2/events returned status %d", response.StatusCode)
+	}
+
+	return parseEventStream(ctx, response.Body, events)
+}
+
+// parseEventStream reads SSE frames off body - one or more "event:"/"data:"
+// lines terminated by a blank line - and emits a decoded Event per frame
+// until body is exhausted, ctx is cancelled, or it returns an error.
+func parseEventStream(ctx context.Context, body io.Reader, events chan<- Event) error {
+	reader := bufio.NewReader(body)
+	var eventName string
+	var dataLines []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		trimmed := strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(trimmed, "event:"):
+			eventName = strings.TrimSpace(strings.TrimPrefix(trimmed, "event:"))
+		case strings.HasPrefix(trimmed, "data:"):
+			dataLines = append(dataLines, strings.TrimSpace(strings.TrimPrefix(trimmed, "data:")))
+		case trimmed == "":
+			if eventName != "" && len(dataLines) > 0 {
+				if decoded, ok := decodeEvent(eventName, strings.Join(dataLines, "\n")); ok {
+					select {
+					case events <- decoded:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+			eventName = ""
+			dataLines = nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func decodeEvent(name, data string) (Event, bool) {
+	switch EventType(name) {
+	case EventTypeStatusUpdate:
+		payload := &StatusUpdateEvent{}
+		if err := json.Unmarshal([]byte(data), payload); err != nil {
+			return Event{}, false
+		}
+		return Event{Type: EventTypeStatusUpdate, StatusUpdate: payload}, true
+	case EventTypeHealthStatusChanged:
+		payload := &HealthStatusChangedEvent{}
+		if err := json.Unmarshal([]byte(data), payload); err != nil {
+			return Event{}, false
+		}
+		return Event{Type: EventTypeHealthStatusChanged, HealthStatusChanged: payload}, true
+	case EventTypeDeploymentStepSuccess:
+		payload := &DeploymentStepSuccessEvent{}
+		if err := json.Unmarshal([]byte(data), payload); err != nil {
+			return Event{}, false
+		}
+		return Event{Type: EventTypeDeploymentStepSuccess, DeploymentStepSuccess: payload}, true
+	case EventTypeAPIPost:
+		payload := &APIPostEvent{}
+		if err := json.Unmarshal([]byte(data), payload); err != nil {
+			return Event{}, false
+		}
+		return Event{Type: EventTypeAPIPost, APIPost: payload}, true
+	case EventTypeLeaderElected:
+		return Event{Type: EventTypeLeaderElected, LeaderElected: &LeaderElectedEvent{}}, true
+	default:
+		return Event{}, false
+	}
+}
@@ -0,0 +1,137 @@
+package marathon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarathon_EventsDecodesSSEFrames(t *testing.T) {
+	t.Parallel()
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "text/event-stream", r.Header.Get("Accept"))
+		flusher := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "event: status_update_event\ndata: {\"taskId\":\"task.1\",\"taskStatus\":\"TASK_RUNNING\"}\n\n")
+		flusher.Flush()
+		fmt.Fprintf(w, "event: leader_elected\ndata: {}\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	parsed, _ := url.Parse(server.URL)
+	m, _ := New(Config{Location: parsed.Host, Protocol: "HTTP"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	events, err := m.Events(ctx)
+
+	// then
+	assert.NoError(t, err)
+
+	first := <-events
+	assert.Equal(t, EventTypeStatusUpdate, first.Type)
+	assert.Equal(t, "task.1", first.StatusUpdate.TaskID)
+	assert.Equal(t, "TASK_RUNNING", first.StatusUpdate.TaskStatus)
+
+	second := <-events
+	assert.Equal(t, EventTypeLeaderElected, second.Type)
+}
+
+func TestMarathon_EventsReconnectsAfterStreamDrop(t *testing.T) {
+	t.Parallel()
+	// given
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := atomic.AddInt32(&connections, 1)
+		flusher := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+
+		if attempt == 1 {
+			fmt.Fprintf(w, "event: leader_elected\ndata: {}\n\n")
+			flusher.Flush()
+			// end the stream after one frame to simulate the connection
+			// dropping mid-flight
+			return
+		}
+
+		fmt.Fprintf(w, "event: deployment_step_success\ndata: {\"id\":\"deploy-1\"}\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	parsed, _ := url.Parse(server.URL)
+	m, _ := New(Config{Location: parsed.Host, Protocol: "HTTP"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// when
+	events, err := m.Events(ctx)
+	assert.NoError(t, err)
+
+	first := <-events
+	assert.Equal(t, EventTypeLeaderElected, first.Type)
+
+	select {
+	case second := <-events:
+		assert.Equal(t, EventTypeDeploymentStepSuccess, second.Type)
+		assert.Equal(t, "deploy-1", second.DeploymentStepSuccess.ID)
+	case <-time.After(eventsMaxBackoff + 5*time.Second):
+		t.Fatal("timed out waiting for the client to reconnect after the stream dropped")
+	}
+
+	assert.True(t, atomic.LoadInt32(&connections) >= 2)
+}
+
+func TestMarathon_EventsStopsSendingOnContextCancelWithNoConsumer(t *testing.T) {
+	t.Parallel()
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "event: leader_elected\ndata: {}\n\n")
+		flusher.Flush()
+		fmt.Fprintf(w, "event: leader_elected\ndata: {}\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	parsed, _ := url.Parse(server.URL)
+	m, _ := New(Config{Location: parsed.Host, Protocol: "HTTP"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// when
+	events, err := m.Events(ctx)
+	assert.NoError(t, err)
+
+	// drop the first event, leaving the second frame's send blocked on
+	// the unbuffered channel with no consumer reading it
+	<-events
+	cancel()
+
+	// then: streamEvents must still close the channel instead of leaking
+	// blocked on the send forever
+	select {
+	case _, open := <-events:
+		if open {
+			<-events
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("events channel was not closed after ctx cancellation")
+	}
+}
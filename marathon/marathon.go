@@ -0,0 +1,252 @@
+// Package marathon implements a thin HTTP client for the Marathon REST
+// API, used to discover the applications and tasks that should be
+// mirrored into Consul.
+package marathon
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/allegro/marathon-consul/apps"
+)
+
+// dcosTokenRefreshInterval bounds how often DCOSTokenFile is re-read, so
+// a rotated token is picked up without requiring a restart.
+const dcosTokenRefreshInterval = 5 * time.Minute
+
+// Config holds the parameters needed to talk to a Marathon cluster.
+type Config struct {
+	Location  string
+	Protocol  string
+	Username  string
+	Password  string
+	VerifySsl bool
+
+	// DCOSToken, when set, authenticates requests with a DC/OS
+	// "Authorization: token=<DCOSToken>" header instead of basic auth.
+	DCOSToken string
+	// DCOSTokenFile, when set, is read for the DC/OS token instead of
+	// DCOSToken, re-read periodically (and after a 401) so the token can
+	// be rotated without restarting the process.
+	DCOSTokenFile string
+
+	// AppSelector is an apps.ParseSelector expression choosing which apps
+	// ConsulApps returns. It defaults to "consul" (i.e. apps carrying a
+	// "consul" label), matching the previous hardcoded behaviour.
+	AppSelector string
+}
+
+// Marathon is a client for the Marathon HTTP API.
+type Marathon struct {
+	config   Config
+	client   *http.Client
+	selector apps.Selector
+
+	tokenMu       sync.Mutex
+	token         string
+	tokenLoadedAt time.Time
+}
+
+// New creates a Marathon client for the given configuration.
+func New(config Config) (*Marathon, error) {
+	transport := &http.Transport{}
+	if !config.VerifySsl {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	selectorExpr := config.AppSelector
+	if selectorExpr == "" {
+		selectorExpr = "consul"
+	}
+	selector, err := apps.ParseSelector(selectorExpr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app selector %q: %s", config.AppSelector, err)
+	}
+
+	m := &Marathon{
+		config:   config,
+		client:   &http.Client{Transport: transport},
+		selector: selector,
+		token:    config.DCOSToken,
+	}
+	if config.DCOSTokenFile != "" {
+		m.reloadToken()
+	}
+	return m, nil
+}
+
+// url builds an absolute URL for path against this client's configured
+// location and protocol. Basic-auth credentials are only embedded in the
+// URL when no DC/OS token is configured, since the token takes precedence
+// over basic auth.
+func (m *Marathon) url(path string) string {
+	base := &url.URL{
+		Scheme: strings.ToLower(m.config.Protocol),
+		Host:   m.config.Location,
+	}
+	if m.config.Username != "" && !m.hasToken() {
+		base.User = url.UserPassword(m.config.Username, m.config.Password)
+	}
+	return base.String() + path
+}
+
+// hasToken reports whether a DC/OS token is configured, either directly
+// or via DCOSTokenFile.
+func (m *Marathon) hasToken() bool {
+	return m.config.DCOSToken != "" || m.config.DCOSTokenFile != ""
+}
+
+// currentToken returns the token to send with requests, re-reading
+// DCOSTokenFile if it is stale.
+func (m *Marathon) currentToken() string {
+	if m.config.DCOSTokenFile == "" {
+		return m.config.DCOSToken
+	}
+
+	m.tokenMu.Lock()
+	stale := time.Since(m.tokenLoadedAt) > dcosTokenRefreshInterval
+	m.tokenMu.Unlock()
+	if stale {
+		m.reloadToken()
+	}
+
+	m.tokenMu.Lock()
+	defer m.tokenMu.Unlock()
+	return m.token
+}
+
+// reloadToken re-reads DCOSTokenFile, keeping the last known good token
+// if the read fails.
+func (m *Marathon) reloadToken() {
+	data, err := ioutil.ReadFile(m.config.DCOSTokenFile)
+	if err != nil {
+		return
+	}
+
+	m.tokenMu.Lock()
+	defer m.tokenMu.Unlock()
+	m.token = strings.TrimSpace(string(data))
+	m.tokenLoadedAt = time.Now()
+}
+
+// get performs an HTTP GET and returns the response body, failing on
+// transport errors or a non-200 status without retrying. The one
+// exception is a 401 while a DCOSTokenFile is configured: the token is
+// re-read once in case it was rotated, and the request is retried.
+func (m *Marathon) get(url string) ([]byte, error) {
+	body, status, err := m.doGet(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == http.StatusUnauthorized && m.config.DCOSTokenFile != "" {
+		m.reloadToken()
+		body, status, err = m.doGet(url)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned status %d", url, status)
+	}
+	return body, nil
+}
+
+func (m *Marathon) doGet(url string) ([]byte, int, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not build GET %s: %s", url, err)
+	}
+	if token := m.currentToken(); token != "" {
+		request.Header.Set("Authorization", "token="+token)
+	}
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not GET %s: %s", url, err)
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read response body of %s: %s", url, err)
+	}
+	return body, response.StatusCode, nil
+}
+
+// ConsulApps returns every app matching the client's AppSelector (all apps
+// carrying a "consul" label by default), together with their tasks. The
+// selector's label equality and presence constraints are pushed down into
+// the Marathon query string to narrow the result set server-side; the
+// full selector is then re-evaluated locally, since Marathon cannot
+// evaluate OR/NOT or app-id matches on our behalf.
+func (m *Marathon) ConsulApps() ([]*apps.App, error) {
+	query := url.Values{"embed": {"apps.tasks"}}
+	if constraints := apps.LabelQueryConstraints(m.selector); len(constraints) > 0 {
+		query.Set("label", strings.Join(constraints, ","))
+	}
+
+	body, err := m.get(m.url("/v2/apps?" + query.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	fetched, err := apps.ParseApps(body)
+	if err != nil {
+		return nil, err
+	}
+	return apps.FilterApps(fetched, m.selector), nil
+}
+
+// App returns a single Marathon application together with its tasks.
+func (m *Marathon) App(appID string) (*apps.App, error) {
+	body, err := m.get(m.url("/v2/apps/" + appID + "?embed=apps.tasks"))
+	if err != nil {
+		return nil, err
+	}
+	return apps.ParseApp(body)
+}
+
+type tasksResponse struct {
+	Tasks []apps.Task `json:"tasks"`
+}
+
+// Tasks returns the tasks of a single Marathon application.
+func (m *Marathon) Tasks(appID string) ([]apps.Task, error) {
+	body, err := m.get(m.url("/v2/apps/" + strings.TrimLeft(appID, "/") + "/tasks"))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &tasksResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return nil, fmt.Errorf("could not parse tasks: %s", err)
+	}
+	return response.Tasks, nil
+}
+
+type leaderResponse struct {
+	Leader string `json:"leader"`
+}
+
+// Leader returns the host:port of the current Marathon leader.
+func (m *Marathon) Leader() (string, error) {
+	body, err := m.get(m.url("/v2/leader"))
+	if err != nil {
+		return "", err
+	}
+
+	response := &leaderResponse{}
+	if err := json.Unmarshal(body, response); err != nil {
+		return "", fmt.Errorf("could not parse leader: %s", err)
+	}
+	return response.Leader, nil
+}